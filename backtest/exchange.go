@@ -0,0 +1,364 @@
+package backtest
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/anthdm/crypto-exchange/client"
+	"github.com/anthdm/crypto-exchange/orderbook"
+	"github.com/anthdm/crypto-exchange/server"
+)
+
+// syntheticUserID is the counterparty every FeedEvent trades as. Its fills
+// are excluded from the Report — they represent the rest of the market, not
+// a strategy under test.
+const syntheticUserID = -1
+
+// Config configures a backtest Exchange.
+type Config struct {
+	Market  server.Market
+	FeeRate server.FeeRate // defaults to server.DefaultFeeRate if zero
+	Latency time.Duration  // simulated order ack latency, added to the clock on every order placement
+	Feed    []FeedEvent
+}
+
+// Exchange drives an in-memory orderbook.Orderbook from a historical feed
+// instead of a live matching engine, implementing client.ExchangeAPI so a
+// strategy written against *client.Client runs against it unchanged.
+type Exchange struct {
+	market  server.Market
+	feeRate server.FeeRate
+	latency time.Duration
+	clock   int64 // simulated time, milliseconds
+
+	ob   *orderbook.Orderbook
+	feed []FeedEvent
+
+	synthBidID int64
+	synthAskID int64
+
+	Report *Report
+}
+
+// NewExchange creates an Exchange seeded with cfg's feed and fee rate.
+func NewExchange(cfg Config) *Exchange {
+	feeRate := cfg.FeeRate
+	if feeRate == (server.FeeRate{}) {
+		feeRate = server.DefaultFeeRate
+	}
+
+	return &Exchange{
+		market:  cfg.Market,
+		feeRate: feeRate,
+		latency: cfg.Latency,
+		ob:      orderbook.NewOrderbook(),
+		feed:    cfg.Feed,
+		Report:  NewReport(),
+	}
+}
+
+var _ client.ExchangeAPI = (*Exchange)(nil)
+
+// Run replays every remaining feed event to completion.
+func (ex *Exchange) Run() {
+	for len(ex.feed) > 0 {
+		ex.Step()
+	}
+}
+
+// Step replays a single feed event, advancing the simulated clock to its
+// timestamp.
+func (ex *Exchange) Step() {
+	if len(ex.feed) == 0 {
+		return
+	}
+
+	e := ex.feed[0]
+	ex.feed = ex.feed[1:]
+	ex.clock = e.Timestamp
+
+	switch e.Kind {
+	case FeedQuote:
+		ex.applyQuote(e)
+	case FeedTrade:
+		ex.applyTrade(e)
+	}
+}
+
+// applyQuote replaces the synthetic resting order on e's side, so the book's
+// top reflects the historical quote.
+func (ex *Exchange) applyQuote(e FeedEvent) {
+	idPtr := &ex.synthAskID
+	if e.Bid {
+		idPtr = &ex.synthBidID
+	}
+
+	if old, ok := ex.ob.Orders[*idPtr]; ok {
+		ex.ob.CancelOrder(old)
+	}
+
+	order := orderbook.NewOrder(e.Bid, e.Size, syntheticUserID)
+	if _, err := ex.ob.PlaceLimitOrder(e.Price, order); err != nil {
+		return
+	}
+	*idPtr = order.ID
+}
+
+// applyTrade replays a historical print as a synthetic market order on the
+// aggressor's side, matching any resting strategy orders at or through
+// e.Price.
+func (ex *Exchange) applyTrade(e FeedEvent) {
+	order := orderbook.NewOrder(e.Bid, e.Size, syntheticUserID)
+	order.TimeInForce = orderbook.IOC
+	matches, err := ex.ob.PlaceLimitOrder(e.Price, order)
+	if err != nil {
+		return
+	}
+	ex.recordMatches(order.ID, matches)
+}
+
+// recordMatches charges maker/taker fees for every match touching a real
+// strategy order and appends a Fill for it. takerOrderID identifies the
+// order that was just placed; the other side of each match was resting.
+func (ex *Exchange) recordMatches(takerOrderID int64, matches []orderbook.Match) {
+	for _, m := range matches {
+		ex.recordSide(m.Bid, m.Price, m.SizeFilled, m.Bid.ID != takerOrderID)
+		ex.recordSide(m.Ask, m.Price, m.SizeFilled, m.Ask.ID != takerOrderID)
+	}
+}
+
+func (ex *Exchange) recordSide(o *orderbook.Order, price, size float64, maker bool) {
+	if o.UserID == syntheticUserID {
+		return
+	}
+
+	feeRate := ex.feeRate.Taker
+	if maker {
+		feeRate = ex.feeRate.Maker
+	}
+
+	ex.Report.record(Fill{
+		OrderID:   o.ID,
+		UserID:    o.UserID,
+		Bid:       o.Bid,
+		Price:     price,
+		Size:      size,
+		Fee:       price * size * feeRate,
+		Maker:     maker,
+		Timestamp: ex.clock,
+	})
+}
+
+// advanceClock simulates the round-trip latency of submitting an order.
+func (ex *Exchange) advanceClock() {
+	ex.clock += ex.latency.Milliseconds()
+}
+
+// GetTrades returns every trade recorded on the book so far.
+func (ex *Exchange) GetTrades(market string) ([]*orderbook.Trade, error) {
+	return ex.ob.Trades, nil
+}
+
+// GetBalances derives userID's balances from the run so far: Inventory in
+// the configured market's base asset, Cash (realized P&L, fees included) in
+// its quote asset. Exchange only replays a single market, so this never
+// reports more than those two symbols.
+func (ex *Exchange) GetBalances(userID int64) (map[string]float64, error) {
+	base, quote := splitMarket(string(ex.market))
+
+	balances := make(map[string]float64)
+	if base != "" {
+		balances[base] = ex.Report.Inventory[userID]
+	}
+	if quote != "" {
+		balances[quote] = ex.Report.Cash[userID]
+	}
+
+	return balances, nil
+}
+
+// splitMarket splits a "BASE/QUOTE" symbol into its two assets.
+func splitMarket(market string) (base, quote string) {
+	parts := strings.SplitN(market, "/", 2)
+	if len(parts) != 2 {
+		return market, ""
+	}
+	return parts[0], parts[1]
+}
+
+// GetOrders lists userID's resting orders.
+func (ex *Exchange) GetOrders(userID int64) (*server.GetOrdersResponse, error) {
+	resp := &server.GetOrdersResponse{}
+	for _, o := range ex.ob.Orders {
+		if o.UserID != userID {
+			continue
+		}
+		wire := server.Order{
+			ID:          o.ID,
+			UserID:      o.UserID,
+			Price:       o.Limit.Price,
+			Size:        o.Size,
+			Bid:         o.Bid,
+			TimeInForce: o.TimeInForce,
+		}
+		if o.Bid {
+			resp.Bids = append(resp.Bids, wire)
+		} else {
+			resp.Asks = append(resp.Asks, wire)
+		}
+	}
+	return resp, nil
+}
+
+// PlaceMarketOrder places a market order and charges fees for every fill.
+func (ex *Exchange) PlaceMarketOrder(p *client.PlaceOrderParams) (*server.PlaceOrderResponse, error) {
+	ex.advanceClock()
+
+	order := orderbook.NewOrder(p.Bid, p.Size, p.UserID)
+	matches := ex.ob.PlaceMarketOrder(order)
+	ex.recordMatches(order.ID, matches)
+
+	return &server.PlaceOrderResponse{OrderID: order.ID}, nil
+}
+
+// GetBestAsk returns the current best ask, which may be a synthetic level
+// sourced from the feed.
+func (ex *Exchange) GetBestAsk() (*server.Order, error) {
+	return ex.GetBestAskFor(ex.market)
+}
+
+// GetBestAskFor returns the current best ask. Exchange only replays a single
+// market, so market must match the one it was configured with.
+func (ex *Exchange) GetBestAskFor(market server.Market) (*server.Order, error) {
+	if market != ex.market {
+		return nil, fmt.Errorf("market %s not configured on this backtest exchange", market)
+	}
+
+	asks := ex.ob.Asks()
+	if len(asks) == 0 {
+		return &server.Order{}, nil
+	}
+	return &server.Order{Price: asks[0].Price}, nil
+}
+
+// GetBestBid returns the current best bid, which may be a synthetic level
+// sourced from the feed.
+func (ex *Exchange) GetBestBid() (*server.Order, error) {
+	return ex.GetBestBidFor(ex.market)
+}
+
+// GetBestBidFor returns the current best bid. Exchange only replays a single
+// market, so market must match the one it was configured with.
+func (ex *Exchange) GetBestBidFor(market server.Market) (*server.Order, error) {
+	if market != ex.market {
+		return nil, fmt.Errorf("market %s not configured on this backtest exchange", market)
+	}
+
+	bids := ex.ob.Bids()
+	if len(bids) == 0 {
+		return &server.Order{}, nil
+	}
+	return &server.Order{Price: bids[0].Price}, nil
+}
+
+// CancelOrder cancels orderID.
+func (ex *Exchange) CancelOrder(orderID int64) error {
+	order, ok := ex.ob.Orders[orderID]
+	if !ok {
+		return fmt.Errorf("order %d not found", orderID)
+	}
+	ex.ob.CancelOrder(order)
+	return nil
+}
+
+// PlaceLimitOrder places a limit order and charges fees for every fill.
+func (ex *Exchange) PlaceLimitOrder(p *client.PlaceOrderParams) (*server.PlaceOrderResponse, error) {
+	if p.Size == 0.0 {
+		return nil, fmt.Errorf("size cannot be 0 when placing a limit order")
+	}
+
+	ex.advanceClock()
+
+	order := orderbook.NewOrder(p.Bid, p.Size, p.UserID)
+	order.TimeInForce = p.TimeInForce
+	matches, err := ex.ob.PlaceLimitOrder(p.Price, order)
+	if err != nil {
+		return nil, err
+	}
+	ex.recordMatches(order.ID, matches)
+
+	return &server.PlaceOrderResponse{OrderID: order.ID}, nil
+}
+
+// PlaceStopOrder places a stop order that converts into a market order, or
+// into a limit order at StopLimitPrice if set, once the book trades through
+// p.StopPrice.
+func (ex *Exchange) PlaceStopOrder(p *client.PlaceOrderParams) (*server.PlaceOrderResponse, error) {
+	if p.StopPrice == 0.0 {
+		return nil, fmt.Errorf("stopPrice cannot be 0 when placing a stop order")
+	}
+
+	ex.advanceClock()
+
+	so := orderbook.NewStopOrder(p.Bid, p.Size, p.UserID, p.StopPrice, p.StopLimitPrice, p.TimeInForce)
+	ex.ob.PlaceStopOrder(so)
+
+	return &server.PlaceOrderResponse{OrderID: so.ID}, nil
+}
+
+// BatchPlaceOrders places every order in sequence and reports a per-order
+// result in request order.
+func (ex *Exchange) BatchPlaceOrders(orders []client.PlaceOrderParams) ([]*server.PlaceOrderResponse, []error) {
+	responses := make([]*server.PlaceOrderResponse, len(orders))
+	errs := make([]error, len(orders))
+
+	for i, p := range orders {
+		responses[i], errs[i] = ex.PlaceLimitOrder(&p)
+	}
+
+	return responses, errs
+}
+
+// BatchCancelOrders cancels every id and reports a per-id error in request
+// order.
+func (ex *Exchange) BatchCancelOrders(ids []int64) []error {
+	errs := make([]error, len(ids))
+	for i, id := range ids {
+		errs[i] = ex.CancelOrder(id)
+	}
+	return errs
+}
+
+// CancelReplace cancels orderID and places new in its place.
+func (ex *Exchange) CancelReplace(orderID int64, new *client.PlaceOrderParams) (*server.PlaceOrderResponse, error) {
+	old, ok := ex.ob.Orders[orderID]
+	if !ok {
+		return nil, fmt.Errorf("order %d not found", orderID)
+	}
+
+	ex.advanceClock()
+
+	newOrder := orderbook.NewOrder(new.Bid, new.Size, new.UserID)
+	newOrder.TimeInForce = new.TimeInForce
+	matches, err := ex.ob.CancelReplace(old, new.Price, newOrder)
+	if err != nil {
+		return nil, err
+	}
+	ex.recordMatches(newOrder.ID, matches)
+
+	return &server.PlaceOrderResponse{OrderID: newOrder.ID}, nil
+}
+
+// BatchRetryPlaceOrders places every order once; there's no transient
+// failure to retry against an in-memory book, so it never leaves anything
+// pending.
+func (ex *Exchange) BatchRetryPlaceOrders(orders []client.PlaceOrderParams, maxAttempts int) ([]*server.PlaceOrderResponse, error) {
+	responses, errs := ex.BatchPlaceOrders(orders)
+	for _, err := range errs {
+		if err != nil {
+			return responses, err
+		}
+	}
+	return responses, nil
+}
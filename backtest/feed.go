@@ -0,0 +1,117 @@
+package backtest
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"strconv"
+)
+
+// FeedKind identifies what a FeedEvent represents.
+type FeedKind string
+
+const (
+	// FeedQuote replaces the synthetic resting order on one side of the
+	// book, so GetBestBid/GetBestAsk track the historical top of book.
+	FeedQuote FeedKind = "QUOTE"
+	// FeedTrade replays a historical print as a synthetic market order,
+	// so it can match against any resting strategy orders.
+	FeedTrade FeedKind = "TRADE"
+)
+
+// FeedEvent is one historical tick: a quote update or a trade print.
+type FeedEvent struct {
+	Timestamp int64    // simulated clock value, in milliseconds
+	Kind      FeedKind // QUOTE or TRADE
+	Bid       bool     // for QUOTE: which side updated; for TRADE: the aggressor side
+	Price     float64
+	Size      float64
+}
+
+// LoadFeedCSV reads a feed from path. Expected columns, no header:
+// timestamp,kind,bid,price,size
+func LoadFeedCSV(path string) ([]FeedEvent, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	r := csv.NewReader(f)
+
+	var events []FeedEvent
+	for {
+		record, err := r.Read()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, err
+		}
+
+		e, err := parseFeedRecord(record)
+		if err != nil {
+			return nil, err
+		}
+		events = append(events, e)
+	}
+
+	return events, nil
+}
+
+func parseFeedRecord(record []string) (FeedEvent, error) {
+	if len(record) != 5 {
+		return FeedEvent{}, fmt.Errorf("feed record %v: expected 5 columns, got %d", record, len(record))
+	}
+
+	timestamp, err := strconv.ParseInt(record[0], 10, 64)
+	if err != nil {
+		return FeedEvent{}, err
+	}
+
+	bid, err := strconv.ParseBool(record[2])
+	if err != nil {
+		return FeedEvent{}, err
+	}
+
+	price, err := strconv.ParseFloat(record[3], 64)
+	if err != nil {
+		return FeedEvent{}, err
+	}
+
+	size, err := strconv.ParseFloat(record[4], 64)
+	if err != nil {
+		return FeedEvent{}, err
+	}
+
+	return FeedEvent{
+		Timestamp: timestamp,
+		Kind:      FeedKind(record[1]),
+		Bid:       bid,
+		Price:     price,
+		Size:      size,
+	}, nil
+}
+
+// LoadFeedJSONL reads a feed from path, one JSON-encoded FeedEvent per line.
+func LoadFeedJSONL(path string) ([]FeedEvent, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	var events []FeedEvent
+	dec := json.NewDecoder(f)
+	for dec.More() {
+		var e FeedEvent
+		if err := dec.Decode(&e); err != nil {
+			return nil, err
+		}
+		events = append(events, e)
+	}
+
+	return events, nil
+}
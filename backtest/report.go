@@ -0,0 +1,56 @@
+package backtest
+
+// Fill is one strategy-owned order's execution against the backtest book.
+type Fill struct {
+	OrderID   int64
+	UserID    int64
+	Bid       bool
+	Price     float64
+	Size      float64
+	Fee       float64
+	Maker     bool // true if this side was resting, false if it was the aggressor
+	Timestamp int64
+}
+
+// Report accumulates fills, inventory and cash flow per user over a run.
+// PnL is computed against it once the feed has finished replaying.
+type Report struct {
+	Fills     []Fill
+	Inventory map[int64]float64 // userID -> net base-asset position
+	Cash      map[int64]float64 // userID -> realized quote-asset flow, fees already deducted
+}
+
+// NewReport creates an empty Report.
+func NewReport() *Report {
+	return &Report{
+		Inventory: make(map[int64]float64),
+		Cash:      make(map[int64]float64),
+	}
+}
+
+// RealizedPnL is the quote-currency cash a user has collected so far,
+// fees included.
+func (r *Report) RealizedPnL(userID int64) float64 {
+	return r.Cash[userID]
+}
+
+// UnrealizedPnL marks a user's open inventory to markPrice.
+func (r *Report) UnrealizedPnL(userID int64, markPrice float64) float64 {
+	return r.Inventory[userID] * markPrice
+}
+
+// PnL is a user's realized cash plus their inventory marked to markPrice.
+func (r *Report) PnL(userID int64, markPrice float64) float64 {
+	return r.RealizedPnL(userID) + r.UnrealizedPnL(userID, markPrice)
+}
+
+func (r *Report) record(f Fill) {
+	r.Fills = append(r.Fills, f)
+
+	size := f.Size
+	if !f.Bid {
+		size = -size
+	}
+	r.Inventory[f.UserID] += size
+	r.Cash[f.UserID] += -size*f.Price - f.Fee
+}
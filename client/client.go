@@ -1,13 +1,15 @@
 package client
 
 import (
-	"bytes" // Importing the bytes package for byte manipulation.
+	"bytes"         // Importing the bytes package for byte manipulation.
 	"encoding/json" // Importing the json package for JSON encoding and decoding.
-	"fmt" // Importing the fmt package for formatted I/O operations.
-	"net/http" // Importing the http package for HTTP client and server implementations.
+	"errors"        // Importing the errors package for constructing per-order batch errors.
+	"fmt"           // Importing the fmt package for formatted I/O operations.
+	"net/http"      // Importing the http package for HTTP client and server implementations.
+	"time"          // Importing the time package for retry backoff.
 
 	"github.com/anthdm/crypto-exchange/orderbook" // Importing the orderbook package for order book operations.
-	"github.com/anthdm/crypto-exchange/server" // Importing the server package for server operations.
+	"github.com/anthdm/crypto-exchange/server"    // Importing the server package for server operations.
 )
 
 // Endpoint defines the base URL for the client.
@@ -15,10 +17,43 @@ const Endpoint = "http://localhost:3000"
 
 // PlaceOrderParams represents the parameters required for placing an order.
 type PlaceOrderParams struct {
-	UserID int64   // UserID holds the user identifier.
-	Bid    bool    // Bid indicates whether the order is a bid or ask.
-	Price  float64 // Price is required only for placing LIMIT orders.
-	Size   float64 // Size represents the quantity of the order.
+	UserID         int64                 // UserID holds the user identifier.
+	Market         server.Market         // Market to trade; defaults to server.MarketETH if empty.
+	Bid            bool                  // Bid indicates whether the order is a bid or ask.
+	Price          float64               // Price is required only for placing LIMIT orders.
+	Size           float64               // Size represents the quantity of the order.
+	TimeInForce    orderbook.TimeInForce // Execution constraint for LIMIT orders; defaults to GTC.
+	StopPrice      float64               // Trigger price, required only for stop orders.
+	StopLimitPrice float64               // If set on a stop order, trigger a LIMIT order at this price instead of a MARKET order.
+}
+
+// marketOrDefault returns m, or server.MarketETH if m is unset.
+func marketOrDefault(m server.Market) server.Market {
+	if m == "" {
+		return server.MarketETH
+	}
+	return m
+}
+
+// ExchangeAPI is the full surface *Client exposes over REST. backtest.Exchange
+// implements it too, so a strategy can be pointed at live REST or at a
+// historical replay without changing a line of strategy code.
+type ExchangeAPI interface {
+	GetTrades(market string) ([]*orderbook.Trade, error)
+	GetOrders(userID int64) (*server.GetOrdersResponse, error)
+	GetBalances(userID int64) (map[string]float64, error)
+	PlaceMarketOrder(p *PlaceOrderParams) (*server.PlaceOrderResponse, error)
+	GetBestAsk() (*server.Order, error)
+	GetBestAskFor(market server.Market) (*server.Order, error)
+	GetBestBid() (*server.Order, error)
+	GetBestBidFor(market server.Market) (*server.Order, error)
+	CancelOrder(orderID int64) error
+	PlaceLimitOrder(p *PlaceOrderParams) (*server.PlaceOrderResponse, error)
+	PlaceStopOrder(p *PlaceOrderParams) (*server.PlaceOrderResponse, error)
+	BatchPlaceOrders(orders []PlaceOrderParams) ([]*server.PlaceOrderResponse, []error)
+	BatchCancelOrders(ids []int64) []error
+	CancelReplace(orderID int64, new *PlaceOrderParams) (*server.PlaceOrderResponse, error)
+	BatchRetryPlaceOrders(orders []PlaceOrderParams, maxAttempts int) ([]*server.PlaceOrderResponse, error)
 }
 
 // Client represents an HTTP client for interacting with the exchange server.
@@ -26,6 +61,8 @@ type Client struct {
 	*http.Client // Embedding the http client for underlying HTTP operations.
 }
 
+var _ ExchangeAPI = (*Client)(nil)
+
 // NewClient creates a new instance of the client.
 func NewClient() *Client {
 	return &Client{
@@ -80,6 +117,28 @@ func (c *Client) GetOrders(userID int64) (*server.GetOrdersResponse, error) {
 	return &orders, nil
 }
 
+// GetBalances retrieves a user's multi-asset balances.
+func (c *Client) GetBalances(userID int64) (map[string]float64, error) {
+	endpoint := fmt.Sprintf("%s/account/%d/balances", Endpoint, userID)
+	req, err := http.NewRequest(http.MethodGet, endpoint, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	resp, err := c.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	balances := server.AccountBalancesResponse{}
+	if err := json.NewDecoder(resp.Body).Decode(&balances); err != nil {
+		return nil, err
+	}
+
+	return balances.Balances, nil
+}
+
 // PlaceMarketOrder places a market order.
 func (c *Client) PlaceMarketOrder(p *PlaceOrderParams) (*server.PlaceOrderResponse, error) {
 	// Constructing the request parameters.
@@ -88,7 +147,7 @@ func (c *Client) PlaceMarketOrder(p *PlaceOrderParams) (*server.PlaceOrderRespon
 		Type:   server.MarketOrder,
 		Bid:    p.Bid,
 		Size:   p.Size,
-		Market: server.MarketETH,
+		Market: marketOrDefault(p.Market),
 	}
 
 	// Encoding the request body into JSON.
@@ -119,9 +178,14 @@ func (c *Client) PlaceMarketOrder(p *PlaceOrderParams) (*server.PlaceOrderRespon
 	return placeOrderResponse, nil
 }
 
-// GetBestAsk retrieves the best ask order.
+// GetBestAsk retrieves the best ask order on the ETH market.
 func (c *Client) GetBestAsk() (*server.Order, error) {
-	endpoint := fmt.Sprintf("%s/book/ETH/ask", Endpoint)
+	return c.GetBestAskFor(server.MarketETH)
+}
+
+// GetBestAskFor retrieves the best ask order for market.
+func (c *Client) GetBestAskFor(market server.Market) (*server.Order, error) {
+	endpoint := fmt.Sprintf("%s/book/%s/ask", Endpoint, market)
 	req, err := http.NewRequest(http.MethodGet, endpoint, nil)
 	if err != nil {
 		return nil, err
@@ -141,9 +205,14 @@ func (c *Client) GetBestAsk() (*server.Order, error) {
 	return order, nil
 }
 
-// GetBestBid retrieves the best bid order.
+// GetBestBid retrieves the best bid order on the ETH market.
 func (c *Client) GetBestBid() (*server.Order, error) {
-	endpoint := fmt.Sprintf("%s/book/ETH/bid", Endpoint)
+	return c.GetBestBidFor(server.MarketETH)
+}
+
+// GetBestBidFor retrieves the best bid order for market.
+func (c *Client) GetBestBidFor(market server.Market) (*server.Order, error) {
+	endpoint := fmt.Sprintf("%s/book/%s/bid", Endpoint, market)
 	req, err := http.NewRequest(http.MethodGet, endpoint, nil)
 	if err != nil {
 		return nil, err
@@ -189,12 +258,13 @@ func (c *Client) PlaceLimitOrder(p *PlaceOrderParams) (*server.PlaceOrderRespons
 
 	// Constructing the request parameters.
 	params := &server.PlaceOrderRequest{
-		UserID: p.UserID,
-		Type:   server.LimitOrder,
-		Bid:    p.Bid,
-		Size:   p.Size,
-		Price:  p.Price,
-		Market: server.MarketETH,
+		UserID:      p.UserID,
+		Type:        server.LimitOrder,
+		Bid:         p.Bid,
+		Size:        p.Size,
+		Price:       p.Price,
+		Market:      marketOrDefault(p.Market),
+		TimeInForce: p.TimeInForce,
 	}
 
 	// Encoding the request body into JSON.
@@ -224,3 +294,237 @@ func (c *Client) PlaceLimitOrder(p *PlaceOrderParams) (*server.PlaceOrderRespons
 
 	return placeOrderResponse, nil
 }
+
+// PlaceStopOrder places a stop order that converts into a market order, or
+// into a limit order at StopLimitPrice if set, once the market trades
+// through p.StopPrice.
+func (c *Client) PlaceStopOrder(p *PlaceOrderParams) (*server.PlaceOrderResponse, error) {
+	if p.StopPrice == 0.0 {
+		return nil, fmt.Errorf("stopPrice cannot be 0 when placing a stop order")
+	}
+
+	// Constructing the request parameters.
+	params := &server.PlaceOrderRequest{
+		UserID:         p.UserID,
+		Type:           server.StopOrder,
+		Bid:            p.Bid,
+		Size:           p.Size,
+		Market:         marketOrDefault(p.Market),
+		TimeInForce:    p.TimeInForce,
+		StopPrice:      p.StopPrice,
+		StopLimitPrice: p.StopLimitPrice,
+	}
+
+	// Encoding the request body into JSON.
+	body, err := json.Marshal(params)
+	if err != nil {
+		return nil, err
+	}
+
+	// Constructing the URL for placing the order.
+	endpoint := Endpoint + "/order"
+	req, err := http.NewRequest(http.MethodPost, endpoint, bytes.NewReader(body))
+	if err != nil {
+		return nil, err
+	}
+
+	resp, err := c.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	// Decoding the response into a PlaceOrderResponse.
+	placeOrderResponse := &server.PlaceOrderResponse{}
+	if err := json.NewDecoder(resp.Body).Decode(placeOrderResponse); err != nil {
+		return nil, err
+	}
+
+	return placeOrderResponse, nil
+}
+
+// toBatchRequest builds the server-side request for a batch limit order.
+func toBatchRequest(p PlaceOrderParams) server.PlaceOrderRequest {
+	return server.PlaceOrderRequest{
+		UserID:      p.UserID,
+		Type:        server.LimitOrder,
+		Bid:         p.Bid,
+		Size:        p.Size,
+		Price:       p.Price,
+		Market:      marketOrDefault(p.Market),
+		TimeInForce: p.TimeInForce,
+	}
+}
+
+// fillErr sets every slot in errs to err, used when a batch call fails
+// before the server can report a per-order result.
+func fillErr(errs []error, err error) {
+	for i := range errs {
+		errs[i] = err
+	}
+}
+
+// BatchPlaceOrders submits all orders in a single request and returns a
+// response/error pair per order, in the same order they were given.
+func (c *Client) BatchPlaceOrders(orders []PlaceOrderParams) ([]*server.PlaceOrderResponse, []error) {
+	reqs := make([]server.PlaceOrderRequest, len(orders))
+	for i, p := range orders {
+		reqs[i] = toBatchRequest(p)
+	}
+
+	responses := make([]*server.PlaceOrderResponse, len(orders))
+	errs := make([]error, len(orders))
+
+	body, err := json.Marshal(reqs)
+	if err != nil {
+		fillErr(errs, err)
+		return responses, errs
+	}
+
+	endpoint := Endpoint + "/orders/batch"
+	req, err := http.NewRequest(http.MethodPost, endpoint, bytes.NewReader(body))
+	if err != nil {
+		fillErr(errs, err)
+		return responses, errs
+	}
+
+	resp, err := c.Do(req)
+	if err != nil {
+		fillErr(errs, err)
+		return responses, errs
+	}
+	defer resp.Body.Close()
+
+	var results []server.BatchOrderResult
+	if err := json.NewDecoder(resp.Body).Decode(&results); err != nil {
+		fillErr(errs, err)
+		return responses, errs
+	}
+
+	for i, r := range results {
+		if r.Err != "" {
+			errs[i] = errors.New(r.Err)
+			continue
+		}
+		responses[i] = &server.PlaceOrderResponse{OrderID: r.OrderID}
+	}
+
+	return responses, errs
+}
+
+// BatchCancelOrders cancels every order ID in a single request and returns
+// a per-ID error, in the same order the IDs were given.
+func (c *Client) BatchCancelOrders(ids []int64) []error {
+	errs := make([]error, len(ids))
+
+	body, err := json.Marshal(ids)
+	if err != nil {
+		fillErr(errs, err)
+		return errs
+	}
+
+	endpoint := Endpoint + "/orders/batch"
+	req, err := http.NewRequest(http.MethodDelete, endpoint, bytes.NewReader(body))
+	if err != nil {
+		fillErr(errs, err)
+		return errs
+	}
+
+	resp, err := c.Do(req)
+	if err != nil {
+		fillErr(errs, err)
+		return errs
+	}
+	defer resp.Body.Close()
+
+	var results []server.BatchOrderResult
+	if err := json.NewDecoder(resp.Body).Decode(&results); err != nil {
+		fillErr(errs, err)
+		return errs
+	}
+
+	for i, r := range results {
+		if r.Err != "" {
+			errs[i] = errors.New(r.Err)
+		}
+	}
+
+	return errs
+}
+
+// CancelReplace atomically cancels orderID and places new in its place, so
+// a market maker's quote never briefly shows zero liquidity at that price.
+func (c *Client) CancelReplace(orderID int64, new *PlaceOrderParams) (*server.PlaceOrderResponse, error) {
+	params := toBatchRequest(*new)
+
+	body, err := json.Marshal(params)
+	if err != nil {
+		return nil, err
+	}
+
+	endpoint := fmt.Sprintf("%s/order/%d/replace", Endpoint, orderID)
+	req, err := http.NewRequest(http.MethodPost, endpoint, bytes.NewReader(body))
+	if err != nil {
+		return nil, err
+	}
+
+	resp, err := c.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	placeOrderResponse := &server.PlaceOrderResponse{}
+	if err := json.NewDecoder(resp.Body).Decode(placeOrderResponse); err != nil {
+		return nil, err
+	}
+
+	return placeOrderResponse, nil
+}
+
+// BatchRetryPlaceOrders submits orders via BatchPlaceOrders, retrying only
+// the orders that failed with exponential backoff, up to maxAttempts total
+// attempts per order.
+func (c *Client) BatchRetryPlaceOrders(orders []PlaceOrderParams, maxAttempts int) ([]*server.PlaceOrderResponse, error) {
+	responses := make([]*server.PlaceOrderResponse, len(orders))
+
+	pending := make([]int, len(orders))
+	for i := range orders {
+		pending[i] = i
+	}
+
+	backoff := 50 * time.Millisecond
+	var lastErrs []error
+
+	for attempt := 0; attempt < maxAttempts && len(pending) > 0; attempt++ {
+		if attempt > 0 {
+			time.Sleep(backoff)
+			backoff *= 2
+		}
+
+		batch := make([]PlaceOrderParams, len(pending))
+		for i, idx := range pending {
+			batch[i] = orders[idx]
+		}
+
+		results, errs := c.BatchPlaceOrders(batch)
+
+		var stillPending []int
+		lastErrs = nil
+		for i, idx := range pending {
+			if errs[i] != nil {
+				stillPending = append(stillPending, idx)
+				lastErrs = append(lastErrs, errs[i])
+				continue
+			}
+			responses[idx] = results[i]
+		}
+		pending = stillPending
+	}
+
+	if len(pending) > 0 {
+		return responses, fmt.Errorf("%d/%d orders failed after %d attempts: %v", len(pending), len(orders), maxAttempts, lastErrs)
+	}
+
+	return responses, nil
+}
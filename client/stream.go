@@ -0,0 +1,139 @@
+package client
+
+import (
+	"encoding/json"
+	"fmt"
+
+	"github.com/gorilla/websocket"
+
+	"github.com/anthdm/crypto-exchange/orderbook"
+)
+
+// WSEndpoint is the base WebSocket URL for the streaming API.
+const WSEndpoint = "ws://localhost:3000/ws"
+
+// streamMessage mirrors server.StreamMessage, the envelope the server writes
+// for every channel message.
+type streamMessage struct {
+	Channel string          `json:"channel"`
+	Seq     uint64          `json:"seq"`
+	Payload json.RawMessage `json:"payload"`
+}
+
+// subscribeRequest mirrors server.subscribeRequest.
+type subscribeRequest struct {
+	Channel string `json:"channel"`
+}
+
+// Stream subscribes to the exchange's WebSocket channels: book@<market>,
+// trades@<market> and user@<userID>. Each Subscribe* call opens its own
+// connection and returns a typed, read-only channel of decoded events.
+type Stream struct {
+	endpoint string
+}
+
+// NewStream creates a Stream against the default exchange WebSocket endpoint.
+func NewStream() *Stream {
+	return &Stream{endpoint: WSEndpoint}
+}
+
+func (s *Stream) dial(channel string) (*websocket.Conn, error) {
+	conn, _, err := websocket.DefaultDialer.Dial(s.endpoint, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := conn.WriteJSON(subscribeRequest{Channel: channel}); err != nil {
+		conn.Close()
+		return nil, err
+	}
+
+	return conn, nil
+}
+
+// SubscribeBook streams incremental L2 volume updates for market.
+func (s *Stream) SubscribeBook(market string) (<-chan orderbook.Event, error) {
+	conn, err := s.dial("book@" + market)
+	if err != nil {
+		return nil, err
+	}
+
+	out := make(chan orderbook.Event)
+	go func() {
+		defer close(out)
+		defer conn.Close()
+
+		for {
+			var msg streamMessage
+			if err := conn.ReadJSON(&msg); err != nil {
+				return
+			}
+
+			var e orderbook.Event
+			if err := json.Unmarshal(msg.Payload, &e); err != nil {
+				continue
+			}
+			out <- e
+		}
+	}()
+
+	return out, nil
+}
+
+// SubscribeTrades streams new trade prints for market.
+func (s *Stream) SubscribeTrades(market string) (<-chan orderbook.Trade, error) {
+	conn, err := s.dial("trades@" + market)
+	if err != nil {
+		return nil, err
+	}
+
+	out := make(chan orderbook.Trade)
+	go func() {
+		defer close(out)
+		defer conn.Close()
+
+		for {
+			var msg streamMessage
+			if err := conn.ReadJSON(&msg); err != nil {
+				return
+			}
+
+			var trade orderbook.Trade
+			if err := json.Unmarshal(msg.Payload, &trade); err != nil {
+				continue
+			}
+			out <- trade
+		}
+	}()
+
+	return out, nil
+}
+
+// SubscribeUserData streams order accepted/filled/cancelled events for userID.
+func (s *Stream) SubscribeUserData(userID int64) (<-chan orderbook.Event, error) {
+	conn, err := s.dial(fmt.Sprintf("user@%d", userID))
+	if err != nil {
+		return nil, err
+	}
+
+	out := make(chan orderbook.Event)
+	go func() {
+		defer close(out)
+		defer conn.Close()
+
+		for {
+			var msg streamMessage
+			if err := conn.ReadJSON(&msg); err != nil {
+				return
+			}
+
+			var e orderbook.Event
+			if err := json.Unmarshal(msg.Payload, &e); err != nil {
+				continue
+			}
+			out <- e
+		}
+	}()
+
+	return out, nil
+}
@@ -4,29 +4,35 @@ import (
 	"time" // Importing the time package for time-related functionalities
 
 	"github.com/anthdm/crypto-exchange/client" // Importing client package from anthdm/crypto-exchange
-	"github.com/sirupsen/logrus" // Importing logrus package for logging
+	"github.com/anthdm/crypto-exchange/server" // Importing server package for the ETH market constant
+	"github.com/sirupsen/logrus"               // Importing logrus package for logging
 )
 
+// maxQuoteAttempts bounds how many attempts, the first included, a bid/ask
+// quote gets on each book update before the maker gives up on it.
+const maxQuoteAttempts = 3
+
 // Config struct to hold configuration parameters
 type Config struct {
-	UserID         int64         // User ID for the market maker
-	OrderSize      float64       // Size of each order
-	MinSpread      float64       // Minimum spread allowed
-	SeedOffset     float64       // Offset for seeding the market
-	ExchangeClient *client.Client // Client for interacting with the exchange
-	MakeInterval   time.Duration // Interval for making orders
-	PriceOffset    float64       // Offset for adjusting price
+	UserID         int64              // User ID for the market maker
+	OrderSize      float64            // Size of each order
+	MinSpread      float64            // Minimum spread allowed
+	SeedOffset     float64            // Offset for seeding the market
+	ExchangeClient client.ExchangeAPI // API for interacting with the exchange (live REST or a backtest.Exchange)
+	MakeInterval   time.Duration      // Interval for making orders
+	PriceOffset    float64            // Offset for adjusting price
 }
 
 // MarketMaker struct to represent a market maker
 type MarketMaker struct {
-	userID         int64           // User ID for the market maker
-	orderSize      float64         // Size of each order
-	minSpread      float64         // Minimum spread allowed
-	seedOffset     float64         // Offset for seeding the market
-	priceOffset    float64         // Offset for adjusting price
-	exchangeClient *client.Client  // Client for interacting with the exchange
-	makeInterval   time.Duration   // Interval for making orders
+	userID         int64              // User ID for the market maker
+	orderSize      float64            // Size of each order
+	minSpread      float64            // Minimum spread allowed
+	seedOffset     float64            // Offset for seeding the market
+	priceOffset    float64            // Offset for adjusting price
+	exchangeClient client.ExchangeAPI // API for interacting with the exchange (live REST or a backtest.Exchange)
+	stream         *client.Stream     // Stream for reacting to book updates without polling
+	makeInterval   time.Duration      // Interval for making orders
 }
 
 // NewMakerMaker creates a new MarketMaker instance with provided config
@@ -37,6 +43,7 @@ func NewMakerMaker(cfg Config) *MarketMaker {
 		minSpread:      cfg.MinSpread,
 		seedOffset:     cfg.SeedOffset,
 		exchangeClient: cfg.ExchangeClient,
+		stream:         client.NewStream(),
 		makeInterval:   cfg.MakeInterval,
 		priceOffset:    cfg.PriceOffset,
 	}
@@ -57,82 +64,73 @@ func (mm *MarketMaker) Start() {
 	go mm.makerLoop()
 }
 
-// makerLoop is the main loop for the market maker
+// makerLoop seeds the market once over REST if it's empty, then reacts to
+// book@ETH diffs pushed over the WebSocket stream instead of polling
+// GetBestBid/GetBestAsk on a ticker. makeInterval is reused as the backoff
+// between reconnect attempts if the stream drops.
 func (mm *MarketMaker) makerLoop() {
-	// Creating a ticker for the make interval
-	ticker := time.NewTicker(mm.makeInterval)
-
 	for {
-		// Getting the best bid from the exchange
-		bestBid, err := mm.exchangeClient.GetBestBid()
-		if err != nil {
+		if err := mm.quoteFromStream(); err != nil {
 			logrus.Error(err)
-			break
 		}
+		time.Sleep(mm.makeInterval)
+	}
+}
 
-		// Getting the best ask from the exchange
-		bestAsk, err := mm.exchangeClient.GetBestAsk()
-		if err != nil {
-			logrus.Error(err)
-			break
-		}
+// quoteFromStream subscribes to the book and re-quotes both sides on every
+// book update that leaves the spread wider than minSpread, until the
+// connection drops.
+func (mm *MarketMaker) quoteFromStream() error {
+	bestBid, err := mm.exchangeClient.GetBestBid()
+	if err != nil {
+		return err
+	}
 
-		// If both bid and ask prices are zero, seed the market
-		if bestAsk.Price == 0 && bestBid.Price == 0 {
-			if err := mm.seedMarket(); err != nil {
-				logrus.Error(err)
-				break
-			}
-			continue
-		}
+	bestAsk, err := mm.exchangeClient.GetBestAsk()
+	if err != nil {
+		return err
+	}
 
-		// Adjusting bid price if necessary
-		if bestBid.Price == 0 {
-			bestBid.Price = bestAsk.Price - mm.priceOffset*2
+	// If both bid and ask prices are zero, seed the market
+	if bestBid.Price == 0 && bestAsk.Price == 0 {
+		if err := mm.seedMarket(); err != nil {
+			return err
 		}
+	}
 
-		// Adjusting ask price if necessary
-		if bestAsk.Price == 0 {
-			bestAsk.Price = bestBid.Price + mm.priceOffset*2
-		}
+	book := newLocalBook()
+	if bestBid.Price != 0 {
+		book.update(true, bestBid.Price, mm.orderSize)
+	}
+	if bestAsk.Price != 0 {
+		book.update(false, bestAsk.Price, mm.orderSize)
+	}
+
+	events, err := mm.stream.SubscribeBook(string(server.MarketETH))
+	if err != nil {
+		return err
+	}
 
-		// Calculating spread
-		spread := bestAsk.Price - bestBid.Price
+	for e := range events {
+		book.update(e.Bid, e.Price, e.Volume)
 
-		// If spread is less than or equal to minSpread, continue to next iteration
-		if spread <= mm.minSpread {
+		bid, ask, ok := book.spread()
+		if !ok || ask-bid <= mm.minSpread {
 			continue
 		}
 
-		// Placing bid order
-		if err := mm.placeOrder(true, bestBid.Price+mm.priceOffset); err != nil {
-			logrus.Error(err)
-			break
+		// Quoting both sides in a single batch request, retrying only the
+		// legs that failed.
+		quotes := []client.PlaceOrderParams{
+			{UserID: mm.userID, Size: mm.orderSize, Bid: true, Price: bid + mm.priceOffset},
+			{UserID: mm.userID, Size: mm.orderSize, Bid: false, Price: ask - mm.priceOffset},
 		}
-
-		// Placing ask order
-		if err := mm.placeOrder(false, bestAsk.Price-mm.priceOffset); err != nil {
-			logrus.Error(err)
-			break
+		if _, err := mm.exchangeClient.BatchRetryPlaceOrders(quotes, maxQuoteAttempts); err != nil {
+			return err
 		}
-
-		// Waiting for the next tick
-		<-ticker.C
 	}
-}
 
-// placeOrder places an order on the exchange
-func (mm *MarketMaker) placeOrder(bid bool, price float64) error {
-	// Creating order parameters
-	bidOrder := &client.PlaceOrderParams{
-		UserID: mm.userID,
-		Size:   mm.orderSize,
-		Bid:    bid,
-		Price:  price,
-	}
-	// Placing the order on the exchange
-	_, err := mm.exchangeClient.PlaceLimitOrder(bidOrder)
-	return err
+	return nil
 }
 
 // seedMarket seeds the market by placing initial bid and ask orders
@@ -170,6 +168,52 @@ func (mm *MarketMaker) seedMarket() error {
 	return err
 }
 
+// localBook is a minimal mirror of the exchange's best bid/ask, kept in sync
+// by applying BOOK_UPDATE diffs from the book stream so the maker never has
+// to poll for the current top of book.
+type localBook struct {
+	bids map[float64]float64 // price -> total volume resting on the bid side
+	asks map[float64]float64 // price -> total volume resting on the ask side
+}
+
+func newLocalBook() *localBook {
+	return &localBook{
+		bids: make(map[float64]float64),
+		asks: make(map[float64]float64),
+	}
+}
+
+// update applies a single price level's new volume, removing the level once
+// it's been fully drained.
+func (b *localBook) update(bid bool, price, volume float64) {
+	side := b.asks
+	if bid {
+		side = b.bids
+	}
+
+	if volume == 0 {
+		delete(side, price)
+		return
+	}
+	side[price] = volume
+}
+
+// spread returns the current best bid and ask, or ok=false if either side is
+// empty.
+func (b *localBook) spread() (bid, ask float64, ok bool) {
+	for price := range b.bids {
+		if price > bid {
+			bid = price
+		}
+	}
+	for price := range b.asks {
+		if ask == 0 || price < ask {
+			ask = price
+		}
+	}
+	return bid, ask, bid != 0 && ask != 0
+}
+
 // simulateFetchCurrentETHPrice simulates fetching current ETH price from another exchange
 func simulateFetchCurrentETHPrice() float64 {
 	// Simulating delay in fetching the price
@@ -0,0 +1,44 @@
+package orderbook
+
+// EventKind identifies what kind of change an Event describes.
+type EventKind string
+
+const (
+	EventOrderAccepted  EventKind = "ORDER_ACCEPTED"  // a new order started resting on the book
+	EventOrderFilled    EventKind = "ORDER_FILLED"    // an order (resting or incoming) was fully filled
+	EventOrderCancelled EventKind = "ORDER_CANCELLED" // a resting order was cancelled
+	EventTradePrint     EventKind = "TRADE"           // a trade was recorded
+	EventBookUpdate     EventKind = "BOOK_UPDATE"     // the total volume at a price level changed
+)
+
+// Event is a single, sequence-numbered change to an Orderbook. Only the
+// fields relevant to Kind are populated.
+type Event struct {
+	Seq    uint64    // monotonically increasing per Orderbook, assigned on publish
+	Kind   EventKind //
+	UserID int64     // set for ORDER_ACCEPTED/FILLED/CANCELLED: whose order changed
+	Order  *Order    // set for ORDER_ACCEPTED/FILLED/CANCELLED
+	Trade  *Trade    // set for TRADE
+	Price  float64   // set for BOOK_UPDATE: the price level that changed
+	Bid    bool      // set for BOOK_UPDATE: which side of the book changed
+	Volume float64   // set for BOOK_UPDATE: the level's new total volume (0 once cleared)
+}
+
+// EventBus receives the ordered stream of events an Orderbook produces as it
+// mutates. Implementations must not block, since Publish is called while
+// Orderbook.mu is held.
+type EventBus interface {
+	Publish(Event)
+}
+
+// publish assigns the next sequence number and forwards e to ob.Bus, if one
+// is configured. Callers must hold ob.mu.
+func (ob *Orderbook) publish(e Event) {
+	if ob.Bus == nil {
+		return
+	}
+
+	ob.seq++
+	e.Seq = ob.seq
+	ob.Bus.Publish(e)
+}
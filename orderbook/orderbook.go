@@ -29,14 +29,25 @@ type Match struct {
 	Price      float64 // Matched price
 }
 
+// TimeInForce constrains how a limit order is executed against the book.
+type TimeInForce string
+
+const (
+	GTC      TimeInForce = "GTC"       // Good-Til-Cancelled: rests on the book until filled or cancelled.
+	IOC      TimeInForce = "IOC"       // Immediate-Or-Cancel: fills what it can immediately, cancels the remainder.
+	FOK      TimeInForce = "FOK"       // Fill-Or-Kill: fills completely and immediately, or is rejected entirely.
+	PostOnly TimeInForce = "POST_ONLY" // Post-Only: rejected outright if it would immediately cross the book.
+)
+
 // Order represents a buy or sell order.
 type Order struct {
-	ID        int64   // Order ID
-	UserID    int64   // User ID
-	Size      float64 // Order quantity
-	Bid       bool    // Order type: buy (true) or sell (false)
-	Limit     *Limit  // Price limit of the order
-	Timestamp int64   // Creation timestamp of the order
+	ID          int64       // Order ID
+	UserID      int64       // User ID
+	Size        float64     // Order quantity
+	Bid         bool        // Order type: buy (true) or sell (false)
+	Limit       *Limit      // Price limit of the order
+	Timestamp   int64       // Creation timestamp of the order
+	TimeInForce TimeInForce // Execution constraint; defaults to GTC when empty
 }
 
 // Orders represents a list of orders.
@@ -190,6 +201,33 @@ func (l *Limit) fillOrder(a, b *Order) Match {
 	}
 }
 
+// StopOrder is a dormant order that converts into a market or limit order
+// once the last trade price crosses TriggerPrice.
+type StopOrder struct {
+	ID           int64       // Order ID
+	UserID       int64       // User ID
+	Bid          bool        // Order type: buy (true) or sell (false)
+	Size         float64     // Order quantity
+	TriggerPrice float64     // Price at which the stop converts into a live order
+	LimitPrice   float64     // If non-zero, convert into a limit order at this price; otherwise a market order
+	TimeInForce  TimeInForce // Applied to the order once triggered, for stop-limit orders
+}
+
+// NewStopOrder creates a new stop order, drawing its ID at random the same
+// way NewOrder does; the triggered order keeps that ID. Like NewOrder, it
+// does not check the ID against IDs already in use.
+func NewStopOrder(bid bool, size float64, userID int64, triggerPrice, limitPrice float64, tif TimeInForce) *StopOrder {
+	return &StopOrder{
+		ID:           int64(rand.Intn(10000000)),
+		UserID:       userID,
+		Bid:          bid,
+		Size:         size,
+		TriggerPrice: triggerPrice,
+		LimitPrice:   limitPrice,
+		TimeInForce:  tif,
+	}
+}
+
 // Orderbook represents an order book of a stock exchange.
 type Orderbook struct {
 	asks []*Limit // List of sell orders
@@ -197,21 +235,26 @@ type Orderbook struct {
 
 	Trades []*Trade // List of completed trades
 
-	mu        sync.RWMutex // Mutex for concurrent access protection
-	AskLimits map[float64]*Limit // Map of sell price limits
-	BidLimits map[float64]*Limit // Map of buy price limits
-	Orders    map[int64]*Order // Map of orders
+	mu         sync.RWMutex         // Mutex for concurrent access protection
+	AskLimits  map[float64]*Limit   // Map of sell price limits
+	BidLimits  map[float64]*Limit   // Map of buy price limits
+	Orders     map[int64]*Order     // Map of orders
+	StopOrders map[int64]*StopOrder // Map of dormant stop orders awaiting trigger
+
+	Bus EventBus // Optional sink for ordered, sequence-numbered state-change events
+	seq uint64   // Last sequence number handed out to Bus
 }
 
 // NewOrderbook creates a new order book.
 func NewOrderbook() *Orderbook {
 	return &Orderbook{
-		asks:      []*Limit{},
-		bids:      []*Limit{},
-		Trades:    []*Trade{},
-		AskLimits: make(map[float64]*Limit),
-		BidLimits: make(map[float64]*Limit),
-		Orders:    make(map[int64]*Order),
+		asks:       []*Limit{},
+		bids:       []*Limit{},
+		Trades:     []*Trade{},
+		AskLimits:  make(map[float64]*Limit),
+		BidLimits:  make(map[float64]*Limit),
+		Orders:     make(map[int64]*Order),
+		StopOrders: make(map[int64]*StopOrder),
 	}
 }
 
@@ -220,6 +263,11 @@ func (ob *Orderbook) PlaceMarketOrder(o *Order) []Match {
 	ob.mu.Lock()
 	defer ob.mu.Unlock()
 
+	return ob.placeMarketOrder(o)
+}
+
+// placeMarketOrder performs the actual matching. Callers must hold ob.mu.
+func (ob *Orderbook) placeMarketOrder(o *Order) []Match {
 	matches := []Match{}
 
 	if o.Bid {
@@ -233,6 +281,9 @@ func (ob *Orderbook) PlaceMarketOrder(o *Order) []Match {
 
 			if len(limit.Orders) == 0 {
 				ob.clearLimit(false, limit)
+				ob.publish(Event{Kind: EventBookUpdate, Price: limit.Price, Bid: false, Volume: 0})
+			} else if len(limitMatches) > 0 {
+				ob.publish(Event{Kind: EventBookUpdate, Price: limit.Price, Bid: false, Volume: limit.TotalVolume})
 			}
 		}
 	} else {
@@ -246,34 +297,54 @@ func (ob *Orderbook) PlaceMarketOrder(o *Order) []Match {
 
 			if len(limit.Orders) == 0 {
 				ob.clearLimit(true, limit)
+				ob.publish(Event{Kind: EventBookUpdate, Price: limit.Price, Bid: true, Volume: 0})
+			} else if len(limitMatches) > 0 {
+				ob.publish(Event{Kind: EventBookUpdate, Price: limit.Price, Bid: true, Volume: limit.TotalVolume})
 			}
 		}
 	}
 
-	for _, match := range matches {
-		trade := &Trade{
-			Price:     match.Price,
-			Size:      match.SizeFilled,
-			Timestamp: time.Now().UnixNano(),
-			Bid:       o.Bid,
-		}
-		ob.Trades = append(ob.Trades, trade)
-	}
-
-	logrus.WithFields(logrus.Fields{
-		"currentPrice": ob.Trades[len(ob.Trades)-1].Price,
-	}).Info()
+	ob.recordTrades(matches, o.Bid)
 
 	return matches
 }
 
-// PlaceLimitOrder places a limit order at a specified price.
-func (ob *Orderbook) PlaceLimitOrder(price float64, o *Order) {
-	var limit *Limit
-
+// PlaceLimitOrder places a limit order at a specified price, honouring the
+// order's TimeInForce. GTC (the default) rests on the book. POST_ONLY is
+// rejected if it would immediately cross the book. IOC fills what it can
+// and cancels the remainder. FOK is rejected outright unless it can be
+// filled in full in a single pass. It returns the matches produced by an
+// IOC/FOK order, or an error if the order was rejected.
+func (ob *Orderbook) PlaceLimitOrder(price float64, o *Order) ([]Match, error) {
 	ob.mu.Lock()
 	defer ob.mu.Unlock()
 
+	return ob.placeLimitOrder(price, o)
+}
+
+// placeLimitOrder performs the actual TimeInForce handling. Callers must hold ob.mu.
+func (ob *Orderbook) placeLimitOrder(price float64, o *Order) ([]Match, error) {
+	tif := o.TimeInForce
+	if tif == "" {
+		tif = GTC
+	}
+
+	if tif == PostOnly && ob.crosses(price, o.Bid) {
+		return nil, fmt.Errorf("post-only order [price: %.2f] would immediately cross the book", price)
+	}
+
+	if tif == IOC || tif == FOK {
+		if tif == FOK && !ob.canFill(price, o) {
+			return nil, fmt.Errorf("fill-or-kill order [size: %.2f] cannot be fully filled", o.Size)
+		}
+
+		matches := ob.matchAtOrBetter(price, o)
+		ob.recordTrades(matches, o.Bid)
+		return matches, nil
+	}
+
+	var limit *Limit
+
 	if o.Bid {
 		limit = ob.BidLimits[price]
 	} else {
@@ -301,6 +372,183 @@ func (ob *Orderbook) PlaceLimitOrder(price float64, o *Order) {
 
 	ob.Orders[o.ID] = o
 	limit.AddOrder(o)
+
+	ob.publish(Event{Kind: EventOrderAccepted, UserID: o.UserID, Order: o})
+	ob.publish(Event{Kind: EventBookUpdate, Price: limit.Price, Bid: o.Bid, Volume: limit.TotalVolume})
+
+	return nil, nil
+}
+
+// crosses reports whether a limit order at price would immediately match
+// against the resting book on the opposite side.
+func (ob *Orderbook) crosses(price float64, bid bool) bool {
+	if bid {
+		asks := ob.Asks()
+		return len(asks) > 0 && price >= asks[0].Price
+	}
+
+	bids := ob.Bids()
+	return len(bids) > 0 && price <= bids[0].Price
+}
+
+// canFill dry-runs whether o could be filled completely by the liquidity
+// available at or better than price, without mutating any book state.
+func (ob *Orderbook) canFill(price float64, o *Order) bool {
+	remaining := o.Size
+
+	if o.Bid {
+		for _, limit := range ob.Asks() {
+			if limit.Price > price {
+				break
+			}
+			remaining -= limit.TotalVolume
+			if remaining <= 0 {
+				return true
+			}
+		}
+	} else {
+		for _, limit := range ob.Bids() {
+			if limit.Price < price {
+				break
+			}
+			remaining -= limit.TotalVolume
+			if remaining <= 0 {
+				return true
+			}
+		}
+	}
+
+	return remaining <= 0
+}
+
+// matchAtOrBetter fills o against the book at or better than price, the
+// same way PlaceMarketOrder does, but never rests the remainder on the book.
+func (ob *Orderbook) matchAtOrBetter(price float64, o *Order) []Match {
+	var matches []Match
+
+	if o.Bid {
+		for _, limit := range ob.Asks() {
+			if o.IsFilled() || limit.Price > price {
+				break
+			}
+			limitMatches := limit.Fill(o)
+			matches = append(matches, limitMatches...)
+			if len(limit.Orders) == 0 {
+				ob.clearLimit(false, limit)
+				ob.publish(Event{Kind: EventBookUpdate, Price: limit.Price, Bid: false, Volume: 0})
+			} else if len(limitMatches) > 0 {
+				ob.publish(Event{Kind: EventBookUpdate, Price: limit.Price, Bid: false, Volume: limit.TotalVolume})
+			}
+		}
+	} else {
+		for _, limit := range ob.Bids() {
+			if o.IsFilled() || limit.Price < price {
+				break
+			}
+			limitMatches := limit.Fill(o)
+			matches = append(matches, limitMatches...)
+			if len(limit.Orders) == 0 {
+				ob.clearLimit(true, limit)
+				ob.publish(Event{Kind: EventBookUpdate, Price: limit.Price, Bid: true, Volume: 0})
+			} else if len(limitMatches) > 0 {
+				ob.publish(Event{Kind: EventBookUpdate, Price: limit.Price, Bid: true, Volume: limit.TotalVolume})
+			}
+		}
+	}
+
+	return matches
+}
+
+// recordTrades appends a Trade per match, logs the latest price, and gives
+// any resting stop orders a chance to trigger off the new prints. Callers
+// must hold ob.mu.
+func (ob *Orderbook) recordTrades(matches []Match, bid bool) {
+	if len(matches) == 0 {
+		return
+	}
+
+	for _, match := range matches {
+		trade := &Trade{
+			Price:     match.Price,
+			Size:      match.SizeFilled,
+			Timestamp: time.Now().UnixNano(),
+			Bid:       bid,
+		}
+		ob.Trades = append(ob.Trades, trade)
+		ob.publish(Event{Kind: EventTradePrint, Trade: trade})
+
+		if match.Bid.IsFilled() {
+			ob.publish(Event{Kind: EventOrderFilled, UserID: match.Bid.UserID, Order: match.Bid})
+		}
+		if match.Ask.IsFilled() {
+			ob.publish(Event{Kind: EventOrderFilled, UserID: match.Ask.UserID, Order: match.Ask})
+		}
+	}
+
+	logrus.WithFields(logrus.Fields{
+		"currentPrice": ob.Trades[len(ob.Trades)-1].Price,
+	}).Info()
+
+	ob.triggerStopOrders(ob.Trades[len(ob.Trades)-1].Price)
+}
+
+// PlaceStopOrder stashes a stop order until the market trades through its
+// trigger price.
+func (ob *Orderbook) PlaceStopOrder(so *StopOrder) {
+	ob.mu.Lock()
+	defer ob.mu.Unlock()
+
+	ob.StopOrders[so.ID] = so
+}
+
+// triggerStopOrders converts any stop order whose trigger has been crossed
+// by lastPrice into a live market or limit order. Callers must hold ob.mu.
+func (ob *Orderbook) triggerStopOrders(lastPrice float64) {
+	var triggered []*StopOrder
+
+	for id, so := range ob.StopOrders {
+		// A buy stop triggers on the way up, a sell stop on the way down.
+		if so.Bid && lastPrice >= so.TriggerPrice {
+			triggered = append(triggered, so)
+			delete(ob.StopOrders, id)
+		} else if !so.Bid && lastPrice <= so.TriggerPrice {
+			triggered = append(triggered, so)
+			delete(ob.StopOrders, id)
+		}
+	}
+
+	for _, so := range triggered {
+		o := &Order{
+			ID:          so.ID,
+			UserID:      so.UserID,
+			Size:        so.Size,
+			Bid:         so.Bid,
+			Timestamp:   time.Now().UnixNano(),
+			TimeInForce: so.TimeInForce,
+		}
+
+		if so.LimitPrice != 0 {
+			ob.placeLimitOrder(so.LimitPrice, o)
+			continue
+		}
+
+		// A stop converting into a market order is triggered passively by an
+		// unrelated trade, not chosen by a caller, so it must never panic
+		// placeMarketOrder the way an oversized direct request legitimately
+		// would. Cap it to whatever liquidity the opposite side actually has.
+		available := ob.BidTotalVolume()
+		if o.Bid {
+			available = ob.AskTotalVolume()
+		}
+		if available == 0 {
+			continue
+		}
+		if o.Size > available {
+			o.Size = available
+		}
+
+		ob.placeMarketOrder(o)
+	}
 }
 
 // clearLimit removes a limit order from the order book.
@@ -328,13 +576,49 @@ func (ob *Orderbook) clearLimit(bid bool, l *Limit) {
 
 // CancelOrder cancels a placed order.
 func (ob *Orderbook) CancelOrder(o *Order) {
+	ob.mu.Lock()
+	defer ob.mu.Unlock()
+
+	ob.cancelOrder(o)
+}
+
+// cancelOrder performs the actual cancellation. Callers must hold ob.mu.
+func (ob *Orderbook) cancelOrder(o *Order) {
 	limit := o.Limit
+	price := limit.Price
 	limit.DeleteOrder(o)
 	delete(ob.Orders, o.ID)
 
+	volume := limit.TotalVolume
 	if len(limit.Orders) == 0 {
 		ob.clearLimit(o.Bid, limit)
+		volume = 0
+	}
+
+	ob.publish(Event{Kind: EventOrderCancelled, UserID: o.UserID, Order: o})
+	ob.publish(Event{Kind: EventBookUpdate, Price: price, Bid: o.Bid, Volume: volume})
+}
+
+// CancelReplace atomically cancels old and places newOrder at price under a
+// single lock, so the book never transiently shows zero liquidity between
+// the two operations. If newOrder is rejected (e.g. an unsatisfiable FOK or
+// POST_ONLY), old is restored to the book instead of staying cancelled.
+func (ob *Orderbook) CancelReplace(old *Order, price float64, newOrder *Order) ([]Match, error) {
+	ob.mu.Lock()
+	defer ob.mu.Unlock()
+
+	oldPrice := old.Limit.Price
+	ob.cancelOrder(old)
+
+	matches, err := ob.placeLimitOrder(price, newOrder)
+	if err != nil {
+		if _, restoreErr := ob.placeLimitOrder(oldPrice, old); restoreErr != nil {
+			return nil, fmt.Errorf("replace rejected (%v) and restoring the original order failed: %w", err, restoreErr)
+		}
+		return nil, err
 	}
+
+	return matches, nil
 }
 
 // BidTotalVolume calculates the total volume of buy orders.
@@ -359,6 +643,28 @@ func (ob *Orderbook) AskTotalVolume() float64 {
 	return totalVolume
 }
 
+// FindOrder looks up id among the book's live orders.
+func (ob *Orderbook) FindOrder(id int64) (*Order, bool) {
+	ob.mu.RLock()
+	defer ob.mu.RUnlock()
+
+	order, ok := ob.Orders[id]
+	return order, ok
+}
+
+// OrdersSnapshot returns a copy of the book's live orders, safe to range
+// over without holding ob.mu.
+func (ob *Orderbook) OrdersSnapshot() map[int64]*Order {
+	ob.mu.RLock()
+	defer ob.mu.RUnlock()
+
+	snapshot := make(map[int64]*Order, len(ob.Orders))
+	for id, o := range ob.Orders {
+		snapshot[id] = o
+	}
+	return snapshot
+}
+
 // Asks returns sorted sell orders.
 func (ob *Orderbook) Asks() []*Limit {
 	sort.Sort(ByBestAsk{ob.asks})
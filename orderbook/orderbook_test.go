@@ -145,3 +145,97 @@ func TestCancelOrderBid(t *testing.T) {
 	_, ok = ob.BidLimits[price]
 	assert(t, ok, false)
 }
+
+// TestPlaceLimitOrderPostOnlyRejected tests that a POST_ONLY order crossing the book is rejected.
+func TestPlaceLimitOrderPostOnlyRejected(t *testing.T) {
+	ob := NewOrderbook()
+	sellOrder := NewOrder(false, 10, 0)
+	ob.PlaceLimitOrder(9_000, sellOrder)
+
+	buyOrder := NewOrder(true, 5, 0)
+	buyOrder.TimeInForce = PostOnly
+
+	_, err := ob.PlaceLimitOrder(10_000, buyOrder)
+	assert(t, err != nil, true)
+	_, ok := ob.Orders[buyOrder.ID]
+	assert(t, ok, false)
+}
+
+// TestPlaceLimitOrderIOCCancelsRemainder tests that an IOC order fills what it can and drops the rest.
+func TestPlaceLimitOrderIOCCancelsRemainder(t *testing.T) {
+	ob := NewOrderbook()
+	sellOrder := NewOrder(false, 5, 0)
+	ob.PlaceLimitOrder(10_000, sellOrder)
+
+	buyOrder := NewOrder(true, 10, 0)
+	buyOrder.TimeInForce = IOC
+
+	matches, err := ob.PlaceLimitOrder(10_000, buyOrder)
+	assert(t, err, nil)
+	assert(t, len(matches), 1)
+	assert(t, matches[0].SizeFilled, 5.0)
+
+	_, ok := ob.Orders[buyOrder.ID]
+	assert(t, ok, false)
+	assert(t, ob.AskTotalVolume(), 0.0)
+}
+
+// TestPlaceLimitOrderFOKRejectedWhenInsufficientLiquidity tests that a FOK order is rejected
+// instead of partially filled when the book cannot cover it in one pass.
+func TestPlaceLimitOrderFOKRejectedWhenInsufficientLiquidity(t *testing.T) {
+	ob := NewOrderbook()
+	sellOrder := NewOrder(false, 5, 0)
+	ob.PlaceLimitOrder(10_000, sellOrder)
+
+	buyOrder := NewOrder(true, 10, 0)
+	buyOrder.TimeInForce = FOK
+
+	matches, err := ob.PlaceLimitOrder(10_000, buyOrder)
+	assert(t, matches == nil, true)
+	assert(t, err != nil, true)
+	assert(t, ob.AskTotalVolume(), 5.0)
+}
+
+// TestStopOrderTriggersOnMarketTrade tests that a stop order converts into a market order
+// once a trade prints through its trigger price.
+func TestStopOrderTriggersOnMarketTrade(t *testing.T) {
+	ob := NewOrderbook()
+
+	// One ask to trade against directly, one to absorb the triggered stop.
+	ob.PlaceLimitOrder(9_600, NewOrder(false, 5, 0))
+	ob.PlaceLimitOrder(9_700, NewOrder(false, 5, 0))
+
+	ob.PlaceStopOrder(&StopOrder{
+		ID:           1,
+		Bid:          true,
+		Size:         5,
+		TriggerPrice: 9_500,
+	})
+	assert(t, len(ob.StopOrders), 1)
+
+	// Trading at 9_600 crosses the 9_500 trigger, converting the stop into a market order.
+	ob.PlaceMarketOrder(NewOrder(true, 5, 0))
+
+	assert(t, len(ob.StopOrders), 0)
+	assert(t, ob.AskTotalVolume(), 0.0)
+}
+
+// TestCancelReplace tests that cancelling and re-placing an order happens
+// atomically, leaving exactly the new order resting on the book.
+func TestCancelReplace(t *testing.T) {
+	ob := NewOrderbook()
+	oldOrder := NewOrder(true, 5, 0)
+	ob.PlaceLimitOrder(9_000, oldOrder)
+
+	newOrder := NewOrder(true, 8, 0)
+	matches, err := ob.CancelReplace(oldOrder, 9_100, newOrder)
+	assert(t, err, nil)
+	assert(t, len(matches), 0)
+
+	_, ok := ob.Orders[oldOrder.ID]
+	assert(t, ok, false)
+
+	_, ok = ob.Orders[newOrder.ID]
+	assert(t, ok, true)
+	assert(t, ob.BidTotalVolume(), 8.0)
+}
@@ -0,0 +1,477 @@
+// Package server exposes the exchange's matching engine over HTTP.
+package server
+
+import (
+	"fmt"
+	"net/http"
+	"strconv"
+	"sync"
+
+	"github.com/anthdm/crypto-exchange/orderbook"
+	"github.com/gin-gonic/gin"
+)
+
+// batchWorkers bounds how many orders in a batch are matched concurrently,
+// so a burst of quote updates doesn't serialize on one goroutine.
+const batchWorkers = 8
+
+// OrderType identifies how an order should be executed against the book.
+type OrderType string
+
+const (
+	MarketOrder OrderType = "MARKET"
+	LimitOrder  OrderType = "LIMIT"
+	StopOrder   OrderType = "STOP"
+)
+
+// Market identifies a tradeable symbol.
+type Market string
+
+const (
+	MarketETH     Market = "ETH"
+	MarketBTCUSDT Market = "BTC/USDT"
+	MarketETHUSDT Market = "ETH/USDT"
+	MarketETHBTC  Market = "ETH/BTC"
+)
+
+// FeeRate holds the maker/taker fee rates charged on a fill, expressed as a
+// fraction of notional (e.g. 0.0010 is 10bps).
+type FeeRate struct {
+	Maker float64 // charged to the resting side of a match
+	Taker float64 // charged to the aggressing side of a match
+}
+
+// DefaultFeeRate is used for any market without an explicit entry in
+// Exchange.FeeRates.
+var DefaultFeeRate = FeeRate{Maker: 0.0010, Taker: 0.0020}
+
+// User represents an account holder on the exchange. Balances maps an asset
+// symbol (e.g. "ETH", "USDT") to the amount the user holds of it.
+type User struct {
+	ID       int64
+	Balances map[string]float64
+}
+
+// NewUser creates a new user with the given starting balances.
+func NewUser(id int64, balances map[string]float64) *User {
+	return &User{ID: id, Balances: balances}
+}
+
+// AccountBalancesResponse lists a user's multi-asset balances.
+type AccountBalancesResponse struct {
+	Balances map[string]float64
+}
+
+// Order is the wire representation of a resting order returned to clients.
+type Order struct {
+	ID             int64
+	UserID         int64
+	Price          float64
+	Size           float64
+	Bid            bool
+	Timestamp      int64
+	TimeInForce    orderbook.TimeInForce
+	StopPrice      float64
+	StopLimitPrice float64
+}
+
+// PlaceOrderRequest is the payload accepted by POST /order.
+type PlaceOrderRequest struct {
+	UserID         int64
+	Type           OrderType
+	Bid            bool
+	Size           float64
+	Price          float64
+	Market         Market
+	TimeInForce    orderbook.TimeInForce // GTC, IOC, FOK or POST_ONLY; defaults to GTC
+	StopPrice      float64               // trigger price for STOP orders
+	StopLimitPrice float64               // if set, the stop converts into a limit at this price instead of a market order
+}
+
+// PlaceOrderResponse is returned after an order has been accepted.
+type PlaceOrderResponse struct {
+	OrderID int64
+}
+
+// GetOrdersResponse lists the resting orders belonging to a user.
+type GetOrdersResponse struct {
+	Asks []Order
+	Bids []Order
+}
+
+// BatchOrderResult is one order's outcome within a batch place/cancel call.
+// Err is empty on success.
+type BatchOrderResult struct {
+	OrderID int64
+	Err     string
+}
+
+// Exchange wires the HTTP API to one orderbook per market.
+type Exchange struct {
+	Users      map[int64]*User
+	Orders     map[int64]int64    // orderID -> userID
+	FeeRates   map[Market]FeeRate // per-market rate a backtest.Exchange should mirror; unused on this live path
+	orderbooks map[Market]*orderbook.Orderbook
+	broker     *Broker
+
+	mu sync.RWMutex // guards Users and Orders, written concurrently by the batch handlers
+}
+
+// NewExchange creates an Exchange with a market for every symbol given,
+// defaulting to a single ETH market if none are given.
+func NewExchange(markets ...Market) *Exchange {
+	if len(markets) == 0 {
+		markets = []Market{MarketETH}
+	}
+
+	ex := &Exchange{
+		Users:      make(map[int64]*User),
+		Orders:     make(map[int64]int64),
+		FeeRates:   make(map[Market]FeeRate),
+		orderbooks: make(map[Market]*orderbook.Orderbook),
+		broker:     NewBroker(),
+	}
+
+	for _, market := range markets {
+		ex.AddMarket(market)
+	}
+
+	return ex
+}
+
+// AddMarket registers a new tradeable symbol with its own orderbook, wired
+// into the event broker and seeded with DefaultFeeRate.
+func (ex *Exchange) AddMarket(market Market) *orderbook.Orderbook {
+	ob := orderbook.NewOrderbook()
+	ob.Bus = &marketEventBus{broker: ex.broker, market: market}
+	ex.orderbooks[market] = ob
+	ex.FeeRates[market] = DefaultFeeRate
+
+	return ob
+}
+
+// FeeRateFor returns the configured fee rate for market, or DefaultFeeRate
+// if none was set. The live Exchange has no fill-level P&L accounting of its
+// own and never calls this itself; it exists so a backtest.Exchange can be
+// seeded from the same rates a strategy would actually pay live.
+func (ex *Exchange) FeeRateFor(market Market) FeeRate {
+	if fr, ok := ex.FeeRates[market]; ok {
+		return fr
+	}
+	return DefaultFeeRate
+}
+
+// StartServer wires up the routes and blocks serving HTTP on :3000.
+func StartServer() error {
+	ex := NewExchange(MarketETH, MarketBTCUSDT, MarketETHUSDT, MarketETHBTC)
+
+	router := gin.Default()
+	router.POST("/order", ex.handlePlaceOrder)
+	router.POST("/order/:id/replace", ex.handleCancelReplaceOrder)
+	router.DELETE("/order/:id", ex.handleCancelOrder)
+	router.GET("/order/:userID", ex.handleGetOrders)
+	router.GET("/account/:userID/balances", ex.handleGetBalances)
+	router.POST("/orders/batch", ex.handleBatchPlaceOrders)
+	router.DELETE("/orders/batch", ex.handleBatchCancelOrders)
+	router.GET("/book/:market/:side", ex.handleGetBestOrder)
+	router.GET("/trades/:market", ex.handleGetTrades)
+	router.GET("/ws", ex.handleWebsocket)
+
+	return router.Run(":3000")
+}
+
+func (ex *Exchange) orderbookFor(market Market) (*orderbook.Orderbook, bool) {
+	ob, ok := ex.orderbooks[market]
+	return ob, ok
+}
+
+// setOrder records id as belonging to userID.
+func (ex *Exchange) setOrder(id, userID int64) {
+	ex.mu.Lock()
+	ex.Orders[id] = userID
+	ex.mu.Unlock()
+}
+
+// deleteOrder forgets id's owner.
+func (ex *Exchange) deleteOrder(id int64) {
+	ex.mu.Lock()
+	delete(ex.Orders, id)
+	ex.mu.Unlock()
+}
+
+// orderOwner returns id's owning userID, if known.
+func (ex *Exchange) orderOwner(id int64) (int64, bool) {
+	ex.mu.RLock()
+	defer ex.mu.RUnlock()
+	userID, ok := ex.Orders[id]
+	return userID, ok
+}
+
+func toOrder(o *orderbook.Order) Order {
+	return Order{
+		ID:          o.ID,
+		UserID:      o.UserID,
+		Price:       o.Limit.Price,
+		Size:        o.Size,
+		Bid:         o.Bid,
+		Timestamp:   o.Timestamp,
+		TimeInForce: o.TimeInForce,
+	}
+}
+
+func (ex *Exchange) handlePlaceOrder(c *gin.Context) {
+	var req PlaceOrderRequest
+	if err := c.BindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"msg": err.Error()})
+		return
+	}
+
+	orderID, err := ex.placeOrder(req)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"msg": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, PlaceOrderResponse{OrderID: orderID})
+}
+
+// placeOrder routes req to the right orderbook and order-placement path,
+// shared by the single-order and batch handlers.
+func (ex *Exchange) placeOrder(req PlaceOrderRequest) (int64, error) {
+	ob, ok := ex.orderbookFor(req.Market)
+	if !ok {
+		return 0, fmt.Errorf("market %s not found", req.Market)
+	}
+
+	switch req.Type {
+	case MarketOrder:
+		order := orderbook.NewOrder(req.Bid, req.Size, req.UserID)
+		ob.PlaceMarketOrder(order)
+		ex.setOrder(order.ID, req.UserID)
+		return order.ID, nil
+
+	case LimitOrder:
+		order := orderbook.NewOrder(req.Bid, req.Size, req.UserID)
+		order.TimeInForce = req.TimeInForce
+		if _, err := ob.PlaceLimitOrder(req.Price, order); err != nil {
+			return 0, err
+		}
+		ex.setOrder(order.ID, req.UserID)
+		return order.ID, nil
+
+	case StopOrder:
+		so := orderbook.NewStopOrder(req.Bid, req.Size, req.UserID, req.StopPrice, req.StopLimitPrice, req.TimeInForce)
+		ob.PlaceStopOrder(so)
+		ex.setOrder(so.ID, req.UserID)
+		return so.ID, nil
+
+	default:
+		return 0, fmt.Errorf("unknown order type %s", req.Type)
+	}
+}
+
+// handleBatchPlaceOrders places every order in the batch concurrently,
+// bounded by batchWorkers, and reports a per-order result in request order.
+func (ex *Exchange) handleBatchPlaceOrders(c *gin.Context) {
+	var reqs []PlaceOrderRequest
+	if err := c.BindJSON(&reqs); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"msg": err.Error()})
+		return
+	}
+
+	results := make([]BatchOrderResult, len(reqs))
+	sem := make(chan struct{}, batchWorkers)
+	var wg sync.WaitGroup
+
+	for i, req := range reqs {
+		wg.Add(1)
+		sem <- struct{}{}
+
+		go func(i int, req PlaceOrderRequest) {
+			defer wg.Done()
+			defer func() { <-sem }()
+
+			orderID, err := ex.placeOrder(req)
+			if err != nil {
+				results[i] = BatchOrderResult{Err: err.Error()}
+				return
+			}
+			results[i] = BatchOrderResult{OrderID: orderID}
+		}(i, req)
+	}
+
+	wg.Wait()
+	c.JSON(http.StatusOK, results)
+}
+
+// findOrder locates the orderbook and order for id across all markets.
+func (ex *Exchange) findOrder(id int64) (*orderbook.Orderbook, *orderbook.Order, bool) {
+	for _, ob := range ex.orderbooks {
+		if order, ok := ob.FindOrder(id); ok {
+			return ob, order, true
+		}
+	}
+	return nil, nil, false
+}
+
+func (ex *Exchange) handleCancelOrder(c *gin.Context) {
+	id, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"msg": err.Error()})
+		return
+	}
+
+	ob, order, ok := ex.findOrder(id)
+	if !ok {
+		c.JSON(http.StatusNotFound, gin.H{"msg": "order not found"})
+		return
+	}
+
+	ob.CancelOrder(order)
+	ex.deleteOrder(id)
+	c.JSON(http.StatusOK, gin.H{"msg": "order deleted"})
+}
+
+// handleBatchCancelOrders cancels every id in the batch and reports a
+// per-id result in request order.
+func (ex *Exchange) handleBatchCancelOrders(c *gin.Context) {
+	var ids []int64
+	if err := c.BindJSON(&ids); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"msg": err.Error()})
+		return
+	}
+
+	results := make([]BatchOrderResult, len(ids))
+	for i, id := range ids {
+		ob, order, ok := ex.findOrder(id)
+		if !ok {
+			results[i] = BatchOrderResult{OrderID: id, Err: "order not found"}
+			continue
+		}
+		ob.CancelOrder(order)
+		ex.deleteOrder(id)
+		results[i] = BatchOrderResult{OrderID: id}
+	}
+
+	c.JSON(http.StatusOK, results)
+}
+
+// handleCancelReplaceOrder atomically cancels an existing order and places
+// its replacement under the same orderbook lock, so the book never briefly
+// shows zero liquidity at that price.
+func (ex *Exchange) handleCancelReplaceOrder(c *gin.Context) {
+	id, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"msg": err.Error()})
+		return
+	}
+
+	ob, oldOrder, ok := ex.findOrder(id)
+	if !ok {
+		c.JSON(http.StatusNotFound, gin.H{"msg": "order not found"})
+		return
+	}
+
+	var req PlaceOrderRequest
+	if err := c.BindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"msg": err.Error()})
+		return
+	}
+
+	newOrder := orderbook.NewOrder(req.Bid, req.Size, req.UserID)
+	newOrder.TimeInForce = req.TimeInForce
+
+	if _, err := ob.CancelReplace(oldOrder, req.Price, newOrder); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"msg": err.Error()})
+		return
+	}
+
+	ex.deleteOrder(id)
+	ex.setOrder(newOrder.ID, req.UserID)
+	c.JSON(http.StatusOK, PlaceOrderResponse{OrderID: newOrder.ID})
+}
+
+func (ex *Exchange) handleGetOrders(c *gin.Context) {
+	userIDStr := c.Param("userID")
+	userID, err := strconv.ParseInt(userIDStr, 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"msg": err.Error()})
+		return
+	}
+
+	resp := GetOrdersResponse{}
+	for _, ob := range ex.orderbooks {
+		for id, order := range ob.OrdersSnapshot() {
+			if owner, ok := ex.orderOwner(id); !ok || owner != userID {
+				continue
+			}
+			if order.Bid {
+				resp.Bids = append(resp.Bids, toOrder(order))
+			} else {
+				resp.Asks = append(resp.Asks, toOrder(order))
+			}
+		}
+	}
+
+	c.JSON(http.StatusOK, resp)
+}
+
+func (ex *Exchange) handleGetBalances(c *gin.Context) {
+	userID, err := strconv.ParseInt(c.Param("userID"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"msg": err.Error()})
+		return
+	}
+
+	ex.mu.RLock()
+	user, ok := ex.Users[userID]
+	ex.mu.RUnlock()
+	if !ok {
+		c.JSON(http.StatusNotFound, gin.H{"msg": "user not found"})
+		return
+	}
+
+	c.JSON(http.StatusOK, AccountBalancesResponse{Balances: user.Balances})
+}
+
+func (ex *Exchange) handleGetBestOrder(c *gin.Context) {
+	market := Market(c.Param("market"))
+	side := c.Param("side")
+
+	ob, ok := ex.orderbookFor(market)
+	if !ok {
+		c.JSON(http.StatusBadRequest, gin.H{"msg": fmt.Sprintf("market %s not found", market)})
+		return
+	}
+
+	switch side {
+	case "ask":
+		if len(ob.Asks()) == 0 {
+			c.JSON(http.StatusOK, Order{})
+			return
+		}
+		best := ob.Asks()[0]
+		c.JSON(http.StatusOK, Order{Price: best.Price})
+	case "bid":
+		if len(ob.Bids()) == 0 {
+			c.JSON(http.StatusOK, Order{})
+			return
+		}
+		best := ob.Bids()[0]
+		c.JSON(http.StatusOK, Order{Price: best.Price})
+	default:
+		c.JSON(http.StatusBadRequest, gin.H{"msg": fmt.Sprintf("unknown side %s", side)})
+	}
+}
+
+func (ex *Exchange) handleGetTrades(c *gin.Context) {
+	market := Market(c.Param("market"))
+
+	ob, ok := ex.orderbookFor(market)
+	if !ok {
+		c.JSON(http.StatusBadRequest, gin.H{"msg": fmt.Sprintf("market %s not found", market)})
+		return
+	}
+
+	c.JSON(http.StatusOK, ob.Trades)
+}
@@ -0,0 +1,151 @@
+package server
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"sync"
+
+	"github.com/gin-gonic/gin"
+	"github.com/gorilla/websocket"
+
+	"github.com/anthdm/crypto-exchange/orderbook"
+)
+
+var upgrader = websocket.Upgrader{
+	ReadBufferSize:  1024,
+	WriteBufferSize: 1024,
+	CheckOrigin:     func(r *http.Request) bool { return true },
+}
+
+// StreamMessage is the envelope written to every channel subscriber.
+type StreamMessage struct {
+	Channel string          `json:"channel"`
+	Seq     uint64          `json:"seq"`
+	Payload json.RawMessage `json:"payload"`
+}
+
+// subscribeRequest is sent by a client right after connecting to pick a
+// channel, e.g. {"channel": "book@ETH"}. A client may send it more than
+// once on the same connection to subscribe to several channels.
+type subscribeRequest struct {
+	Channel string `json:"channel"`
+}
+
+// Broker fans orderbook.Events out to the WebSocket connections subscribed
+// to the channel they belong on: book@<market>, trades@<market> or
+// user@<userID>.
+type Broker struct {
+	mu          sync.RWMutex
+	subscribers map[string]map[chan StreamMessage]struct{}
+}
+
+// NewBroker creates an empty Broker.
+func NewBroker() *Broker {
+	return &Broker{subscribers: make(map[string]map[chan StreamMessage]struct{})}
+}
+
+func (b *Broker) subscribe(channel string, out chan StreamMessage) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if b.subscribers[channel] == nil {
+		b.subscribers[channel] = make(map[chan StreamMessage]struct{})
+	}
+	b.subscribers[channel][out] = struct{}{}
+}
+
+func (b *Broker) unsubscribe(channel string, out chan StreamMessage) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	delete(b.subscribers[channel], out)
+}
+
+// broadcast marshals payload once and fans it out to every subscriber of
+// channel. A subscriber whose buffer is full misses the message rather than
+// being allowed to block the matching engine.
+func (b *Broker) broadcast(channel string, seq uint64, payload any) {
+	raw, err := json.Marshal(payload)
+	if err != nil {
+		return
+	}
+	msg := StreamMessage{Channel: channel, Seq: seq, Payload: raw}
+
+	b.mu.RLock()
+	defer b.mu.RUnlock()
+
+	for out := range b.subscribers[channel] {
+		select {
+		case out <- msg:
+		default:
+		}
+	}
+}
+
+// marketEventBus adapts a Broker into the per-market orderbook.EventBus each
+// Orderbook is configured with.
+type marketEventBus struct {
+	broker *Broker
+	market Market
+}
+
+// Publish implements orderbook.EventBus, routing each event to the
+// book@/trades@/user@ channel it belongs on.
+func (m *marketEventBus) Publish(e orderbook.Event) {
+	switch e.Kind {
+	case orderbook.EventTradePrint:
+		m.broker.broadcast("trades@"+string(m.market), e.Seq, e.Trade)
+	case orderbook.EventBookUpdate:
+		m.broker.broadcast("book@"+string(m.market), e.Seq, e)
+	case orderbook.EventOrderAccepted, orderbook.EventOrderFilled, orderbook.EventOrderCancelled:
+		if e.Order != nil && e.Order.Limit != nil {
+			// A resting order's Limit lists the order itself, a cycle JSON can't encode.
+			order := *e.Order
+			order.Limit = nil
+			e.Order = &order
+		}
+		m.broker.broadcast(fmt.Sprintf("user@%d", e.UserID), e.Seq, e)
+	}
+}
+
+// handleWebsocket upgrades the connection and streams every channel the
+// client subscribes to until it disconnects.
+func (ex *Exchange) handleWebsocket(c *gin.Context) {
+	conn, err := upgrader.Upgrade(c.Writer, c.Request, nil)
+	if err != nil {
+		return
+	}
+	defer conn.Close()
+
+	out := make(chan StreamMessage, 256)
+	done := make(chan struct{})
+	var channels []string
+
+	go func() {
+		for {
+			select {
+			case msg := <-out:
+				if err := conn.WriteJSON(msg); err != nil {
+					return
+				}
+			case <-done:
+				return
+			}
+		}
+	}()
+
+	for {
+		var req subscribeRequest
+		if err := conn.ReadJSON(&req); err != nil {
+			break
+		}
+		ex.broker.subscribe(req.Channel, out)
+		channels = append(channels, req.Channel)
+	}
+
+	close(done)
+	for _, channel := range channels {
+		ex.broker.unsubscribe(channel, out)
+	}
+}
@@ -0,0 +1,34 @@
+package rebalance
+
+import (
+	"os"
+
+	"gopkg.in/yaml.v3"
+)
+
+// Config configures a Strategy. PollIntervalMs is stored as milliseconds
+// rather than a time.Duration since YAML has no native duration type.
+type Config struct {
+	UserID         int64              `yaml:"userID"`
+	QuoteAsset     string             `yaml:"quoteAsset"`    // numeraire asset, e.g. "USDT"; always priced at 1
+	TargetWeights  map[string]float64 `yaml:"targetWeights"` // asset -> target fraction of total portfolio value
+	Markets        map[string]string  `yaml:"markets"`       // asset -> market quoting it against QuoteAsset, e.g. "ETH": "ETH/USDT"
+	Band           float64            `yaml:"band"`          // rebalance an asset only once |deviation|/total exceeds this fraction
+	PollIntervalMs int64              `yaml:"pollIntervalMs"`
+	DryRun         bool               `yaml:"dryRun"` // log intended orders instead of submitting them
+}
+
+// LoadConfig reads and parses a Strategy Config from a YAML file at path.
+func LoadConfig(path string) (*Config, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	var cfg Config
+	if err := yaml.Unmarshal(data, &cfg); err != nil {
+		return nil, err
+	}
+
+	return &cfg, nil
+}
@@ -0,0 +1,139 @@
+package rebalance
+
+import (
+	"math"
+	"time"
+
+	"github.com/sirupsen/logrus"
+
+	"github.com/anthdm/crypto-exchange/client"
+	"github.com/anthdm/crypto-exchange/server"
+)
+
+// Strategy periodically compares a user's balances against
+// Config.TargetWeights and issues limit orders to close any asset's
+// deviation once it drifts past Config.Band.
+type Strategy struct {
+	cfg            Config
+	exchangeClient client.ExchangeAPI
+}
+
+// New creates a Strategy driven by exchangeClient, which may be a live
+// *client.Client or a *backtest.Exchange.
+func New(cfg Config, exchangeClient client.ExchangeAPI) *Strategy {
+	return &Strategy{cfg: cfg, exchangeClient: exchangeClient}
+}
+
+// Start runs the strategy's poll loop in its own goroutine.
+func (s *Strategy) Start() {
+	logrus.WithFields(logrus.Fields{
+		"targetWeights": s.cfg.TargetWeights,
+		"band":          s.cfg.Band,
+		"dryRun":        s.cfg.DryRun,
+	}).Info("starting rebalance strategy")
+
+	go s.loop()
+}
+
+func (s *Strategy) loop() {
+	ticker := time.NewTicker(time.Duration(s.cfg.PollIntervalMs) * time.Millisecond)
+
+	for range ticker.C {
+		if err := s.rebalance(); err != nil {
+			logrus.Error(err)
+		}
+	}
+}
+
+// rebalance reads balances and mid-prices, then issues an order for every
+// asset whose weight has drifted past Config.Band.
+func (s *Strategy) rebalance() error {
+	balances, err := s.exchangeClient.GetBalances(s.cfg.UserID)
+	if err != nil {
+		return err
+	}
+
+	prices := map[string]float64{s.cfg.QuoteAsset: 1}
+	for asset, market := range s.cfg.Markets {
+		price, err := s.midPrice(market)
+		if err != nil {
+			return err
+		}
+		prices[asset] = price
+	}
+
+	total := 0.0
+	for asset, balance := range balances {
+		total += balance * prices[asset]
+	}
+	if total == 0 {
+		return nil
+	}
+
+	for asset, target := range s.cfg.TargetWeights {
+		if asset == s.cfg.QuoteAsset {
+			continue
+		}
+
+		price := prices[asset]
+		if price == 0 {
+			continue
+		}
+
+		currentValue := balances[asset] * price
+		targetValue := total * target
+
+		deviation := (currentValue - targetValue) / total
+		if deviation > -s.cfg.Band && deviation < s.cfg.Band {
+			continue
+		}
+
+		// Quote-currency notional still needed to reach target; buying
+		// converts it into a base-asset quantity at the current mid.
+		notional := targetValue - currentValue
+		order := client.PlaceOrderParams{
+			UserID: s.cfg.UserID,
+			Market: server.Market(s.cfg.Markets[asset]),
+			Bid:    notional > 0,
+			Price:  price,
+			Size:   math.Abs(notional) / price,
+		}
+
+		if s.cfg.DryRun {
+			logrus.WithFields(logrus.Fields{
+				"asset":  asset,
+				"market": order.Market,
+				"bid":    order.Bid,
+				"price":  order.Price,
+				"size":   order.Size,
+			}).Info("dry run: would place rebalance order")
+			continue
+		}
+
+		if _, err := s.exchangeClient.PlaceLimitOrder(&order); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// midPrice is the average of the best bid and ask on market, or 0 if either
+// side is empty.
+func (s *Strategy) midPrice(market string) (float64, error) {
+	bid, err := s.exchangeClient.GetBestBidFor(server.Market(market))
+	if err != nil {
+		return 0, err
+	}
+
+	ask, err := s.exchangeClient.GetBestAskFor(server.Market(market))
+	if err != nil {
+		return 0, err
+	}
+
+	if bid.Price == 0 || ask.Price == 0 {
+		return 0, nil
+	}
+
+	return (bid.Price + ask.Price) / 2, nil
+}
@@ -0,0 +1,39 @@
+package tri
+
+import (
+	"os"
+
+	"gopkg.in/yaml.v3"
+)
+
+// LegConfig describes one edge of the triangular cycle.
+type LegConfig struct {
+	Market string `yaml:"market"` // symbol, e.g. "BTC/USDT"
+	Bid    bool   `yaml:"bid"`    // true: we buy the base asset (cross the ask); false: we sell it (cross the bid)
+}
+
+// Config configures a Strategy. PollIntervalMs is stored as milliseconds
+// rather than a time.Duration since YAML has no native duration type.
+type Config struct {
+	UserID         int64              `yaml:"userID"`
+	Legs           [3]LegConfig       `yaml:"legs"`
+	Size           float64            `yaml:"size"`           // base-asset size quoted on the first leg
+	MinSpreadRatio float64            `yaml:"minSpreadRatio"` // fire when the cycle's implied rate exceeds 1+MinSpreadRatio
+	PollIntervalMs int64              `yaml:"pollIntervalMs"`
+	InventoryCaps  map[string]float64 `yaml:"inventoryCaps"` // asset symbol -> max absolute exposure; only the legs' base assets are tracked and checked, so a cap on an asset that is never a leg's base (e.g. USDT) is not enforced
+}
+
+// LoadConfig reads and parses a Strategy Config from a YAML file at path.
+func LoadConfig(path string) (*Config, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	var cfg Config
+	if err := yaml.Unmarshal(data, &cfg); err != nil {
+		return nil, err
+	}
+
+	return &cfg, nil
+}
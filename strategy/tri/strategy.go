@@ -0,0 +1,225 @@
+package tri
+
+import (
+	"math"
+	"strings"
+	"time"
+
+	"github.com/sirupsen/logrus"
+
+	"github.com/anthdm/crypto-exchange/client"
+	"github.com/anthdm/crypto-exchange/server"
+)
+
+// Strategy watches the best bid/ask across Config.Legs' three markets and
+// fires all three as a single batch once their implied rates compound to a
+// profit, mirroring mm.MarketMaker's own goroutine-loop shape.
+type Strategy struct {
+	cfg            Config
+	exchangeClient client.ExchangeAPI
+	inventory      map[string]float64 // asset symbol -> our estimated net exposure
+}
+
+// New creates a Strategy driven by exchangeClient, which may be a live
+// *client.Client or a *backtest.Exchange.
+func New(cfg Config, exchangeClient client.ExchangeAPI) *Strategy {
+	return &Strategy{
+		cfg:            cfg,
+		exchangeClient: exchangeClient,
+		inventory:      make(map[string]float64),
+	}
+}
+
+// Start runs the strategy's poll loop in its own goroutine.
+func (s *Strategy) Start() {
+	logrus.WithFields(logrus.Fields{
+		"legs":           s.cfg.Legs,
+		"size":           s.cfg.Size,
+		"minSpreadRatio": s.cfg.MinSpreadRatio,
+	}).Info("starting triangular arbitrage strategy")
+
+	go s.loop()
+}
+
+func (s *Strategy) loop() {
+	ticker := time.NewTicker(time.Duration(s.cfg.PollIntervalMs) * time.Millisecond)
+
+	for range ticker.C {
+		if err := s.tryArb(); err != nil {
+			logrus.Error(err)
+		}
+	}
+}
+
+// legQuote is one leg's touch price and its contribution to the cycle's
+// implied rate.
+type legQuote struct {
+	price float64
+	rate  float64
+}
+
+// tryArb reads the best quote on every leg, and fires the cycle if the
+// product of their implied rates clears 1+MinSpreadRatio and every leg's
+// base asset stays within its inventory cap.
+func (s *Strategy) tryArb() error {
+	quotes := make([]legQuote, len(s.cfg.Legs))
+	product := 1.0
+	for i, leg := range s.cfg.Legs {
+		q, err := s.impliedRate(leg)
+		if err != nil {
+			return err
+		}
+		if q.rate == 0 {
+			// One of the books is empty; nothing to arb against yet.
+			return nil
+		}
+		quotes[i] = q
+		product *= q.rate
+	}
+
+	if product <= 1+s.cfg.MinSpreadRatio {
+		return nil
+	}
+
+	sizes := s.legSizes(quotes)
+
+	if !s.withinCaps(sizes) {
+		return nil
+	}
+
+	orders := make([]client.PlaceOrderParams, len(s.cfg.Legs))
+	for i, leg := range s.cfg.Legs {
+		orders[i] = client.PlaceOrderParams{
+			UserID: s.cfg.UserID,
+			Market: server.Market(leg.Market),
+			Bid:    leg.Bid,
+			Price:  quotes[i].price,
+			Size:   sizes[i],
+		}
+	}
+
+	_, errs := s.exchangeClient.BatchPlaceOrders(orders)
+	for _, err := range errs {
+		if err != nil {
+			return err
+		}
+	}
+
+	s.applyFill(sizes)
+
+	return nil
+}
+
+// legSizes converts Config.Size, given directly as the first leg's
+// base-asset trade size, into a size for every other leg: each leg trades
+// whatever it holds coming in, converted through its own implied rate when
+// it's buying (Size = held * rate) and passed through unchanged when it's
+// selling (the rate only scales what that leg hands off to the next one).
+// This keeps a leg quoted in a different pair size-matched instead of
+// reusing the raw config number.
+func (s *Strategy) legSizes(quotes []legQuote) []float64 {
+	sizes := make([]float64, len(quotes))
+	sizes[0] = s.cfg.Size
+	held := heldAfterLeg(s.cfg.Legs[0].Bid, sizes[0], quotes[0].rate)
+
+	for i := 1; i < len(quotes); i++ {
+		leg := s.cfg.Legs[i]
+		sizes[i] = sizeForLeg(leg.Bid, held, quotes[i].rate)
+		held = heldAfterLeg(leg.Bid, sizes[i], quotes[i].rate)
+	}
+
+	return sizes
+}
+
+// sizeForLeg converts held (the asset amount on hand before this leg) into
+// this leg's order size: buying converts it from quote to base at rate,
+// selling trades held itself since it's already in base units.
+func sizeForLeg(bid bool, held, rate float64) float64 {
+	if bid {
+		return held * rate
+	}
+	return held
+}
+
+// heldAfterLeg returns what we hold once size has filled: buying yields
+// size units of the base asset; selling yields size*rate units of the quote
+// asset.
+func heldAfterLeg(bid bool, size, rate float64) float64 {
+	if bid {
+		return size
+	}
+	return size * rate
+}
+
+// impliedRate returns leg's touch price and its contribution to the cycle's
+// rate product: 1/price when we're buying the base asset (paying the ask),
+// or price itself when we're selling it (hitting the bid). rate is 0 if
+// that side is empty.
+func (s *Strategy) impliedRate(leg LegConfig) (legQuote, error) {
+	if leg.Bid {
+		ask, err := s.exchangeClient.GetBestAskFor(server.Market(leg.Market))
+		if err != nil {
+			return legQuote{}, err
+		}
+		if ask.Price == 0 {
+			return legQuote{}, nil
+		}
+		return legQuote{price: ask.Price, rate: 1 / ask.Price}, nil
+	}
+
+	bid, err := s.exchangeClient.GetBestBidFor(server.Market(leg.Market))
+	if err != nil {
+		return legQuote{}, err
+	}
+	if bid.Price == 0 {
+		return legQuote{}, nil
+	}
+	return legQuote{price: bid.Price, rate: bid.Price}, nil
+}
+
+// withinCaps reports whether firing this cycle at sizes (one quantity per
+// leg, in that leg's own base asset) would keep every leg's base asset
+// within its configured inventory cap.
+func (s *Strategy) withinCaps(sizes []float64) bool {
+	for i, leg := range s.cfg.Legs {
+		base, _ := splitMarket(leg.Market)
+		limit, ok := s.cfg.InventoryCaps[base]
+		if !ok {
+			continue
+		}
+
+		projected := s.inventory[base]
+		if leg.Bid {
+			projected += sizes[i]
+		} else {
+			projected -= sizes[i]
+		}
+
+		if math.Abs(projected) > limit {
+			return false
+		}
+	}
+
+	return true
+}
+
+// applyFill updates our local inventory estimate after a cycle fires at sizes.
+func (s *Strategy) applyFill(sizes []float64) {
+	for i, leg := range s.cfg.Legs {
+		base, _ := splitMarket(leg.Market)
+		if leg.Bid {
+			s.inventory[base] += sizes[i]
+		} else {
+			s.inventory[base] -= sizes[i]
+		}
+	}
+}
+
+// splitMarket splits a "BASE/QUOTE" symbol into its two assets.
+func splitMarket(market string) (base, quote string) {
+	parts := strings.SplitN(market, "/", 2)
+	if len(parts) != 2 {
+		return market, ""
+	}
+	return parts[0], parts[1]
+}